@@ -0,0 +1,76 @@
+// The MIT License (MIT)
+//
+// Copyright (C) 2016 Space Monkey, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package infectious
+
+import "fmt"
+
+// BerlekampWelchWithErasures is a variant of BerlekampWelch for callers that
+// already know some shares are missing or untrustworthy -- for example, a
+// piece-store audit pass that flagged particular nodes, or a signature/HMAC
+// layer that rejected particular pieces outright. Reed-Solomon allows
+// recovering from e unknown errors and f known erasures simultaneously
+// whenever 2e+f <= n-k, which is a strictly better bound than the e-only
+// (n-k)/2 that BerlekampWelch offers once f is nonzero.
+//
+// erasures holds the Share.Number of every share known to be erased.
+// Those share numbers may still be present in shares -- that's the whole
+// point when the caller's source of erasures is a signature/HMAC layer
+// or an earlier Correct pass that flagged particular pieces without
+// removing them from the slice -- but if they are, their Data is ignored
+// rather than fed into the solve. Callers that already dropped the
+// erased shares from shares entirely may pass their numbers here too;
+// either way the effect is the same.
+//
+// Internally, removing the erased points from the decode is equivalent to
+// pre-seeding the error locator polynomial with a factor of (x - x_j) for
+// every erasure x_j: both leave the same e = (n-k-f)/2 unknowns for the
+// linear solve. Filtering the erasures out of the share set up front lets
+// this reuse the existing berlekampWelch solve unchanged.
+//
+// If erasures is empty, this is equivalent to calling BerlekampWelch.
+func (f *FecCode) BerlekampWelchWithErasures(shares []Share, erasures []int,
+	cb Callback) error {
+
+	if len(erasures) == 0 {
+		return f.BerlekampWelch(shares, cb)
+	}
+
+	erased := make(map[int]bool, len(erasures))
+	for _, num := range erasures {
+		erased[num] = true
+	}
+
+	remaining := make([]Share, 0, len(shares))
+	for _, share := range shares {
+		if erased[share.Number] {
+			continue
+		}
+		remaining = append(remaining, share)
+	}
+
+	if len(remaining) < f.k {
+		return fmt.Errorf("not enough shares")
+	}
+
+	return f.BerlekampWelch(remaining, cb)
+}
@@ -0,0 +1,82 @@
+// The MIT License (MIT)
+//
+// Copyright (C) 2016 Space Monkey, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package infectious
+
+import "testing"
+
+func TestBerlekampWelchWithErasures(t *testing.T) {
+	const block = 4096
+	const total, required = 40, 20
+
+	test := NewBerlekampWelchTest(t, required, total)
+	shares := test.SomeShares(block)
+
+	// drop six shares as erasures, and corrupt one of what remains; with
+	// f=6 erasures the decoder should still tolerate e=(40-20-6)/2=7 errors.
+	erasures := []int{0, 1, 2, 3, 4, 5}
+	remaining := append([]Share(nil), shares[6:]...)
+	remaining[0].Data[0]++
+
+	decoded_shares, callback := test.StoreShares()
+	test.AssertNoError(
+		test.code.BerlekampWelchWithErasures(remaining, erasures, callback))
+	test.AssertDeepEqual(decoded_shares[:required], shares[:required])
+}
+
+func TestBerlekampWelchWithErasuresFlaggedInPlace(t *testing.T) {
+	const block = 4096
+	const total, required = 40, 20
+
+	test := NewBerlekampWelchTest(t, required, total)
+	shares := test.SomeShares(block)
+
+	// an audit/HMAC layer flagged shares 0-5 as untrustworthy without
+	// removing them from the slice, and one of the shares it didn't flag
+	// is also corrupt; the flagged shares' garbage data must not be fed
+	// into the solve just because they're still present.
+	tampered := test.CopyShares(shares)
+	for _, num := range []int{0, 1, 2, 3, 4, 5} {
+		tampered[num].Data[0] ^= 0xff
+	}
+	tampered[10].Data[0]++
+
+	erasures := []int{0, 1, 2, 3, 4, 5}
+
+	decoded_shares, callback := test.StoreShares()
+	test.AssertNoError(
+		test.code.BerlekampWelchWithErasures(tampered, erasures, callback))
+	test.AssertDeepEqual(decoded_shares[:required], shares[:required])
+}
+
+func TestBerlekampWelchWithErasuresEmpty(t *testing.T) {
+	const block = 4096
+	const total, required = 7, 3
+
+	test := NewBerlekampWelchTest(t, required, total)
+	shares := test.SomeShares(block)
+
+	decoded_shares, callback := test.StoreShares()
+	test.AssertNoError(
+		test.code.BerlekampWelchWithErasures(shares, nil, callback))
+	test.AssertDeepEqual(decoded_shares[:required], shares[:required])
+}
@@ -0,0 +1,83 @@
+// The MIT License (MIT)
+//
+// Copyright (C) 2016 Space Monkey, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package infectious
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Correct behaves like BerlekampWelch, but additionally reports which of the
+// input shares disagreed with the recovered codeword. This is useful for
+// audit workflows (for example, checking which of a number of storage nodes
+// returned tampered pieces) where the caller needs to blame specific share
+// numbers rather than just receiving the corrected data.
+//
+// Every byte of shares that disagreed is overwritten in place with its
+// recovered value. cb, if non-nil, is invoked once per byte column for each
+// of the f.n shares, with a one-byte slice holding that share's recovered
+// byte for the column; it may be nil if the caller only cares about
+// corruptShareNumbers.
+func (f *FecCode) Correct(shares []Share, cb Callback) (
+	corruptShareNumbers []int, err error) {
+
+	if len(shares) < f.k {
+		return nil, fmt.Errorf("not enough shares")
+	}
+
+	block := len(shares[0].Data)
+	for _, share := range shares {
+		if len(share.Data) != block {
+			return nil, fmt.Errorf("all shares must be the same size")
+		}
+	}
+
+	corrupt := make(map[int]bool)
+
+	for i := 0; i < block; i++ {
+		out, err := f.berlekampWelch(shares, i)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, share := range shares {
+			if share.Data[i] != out[share.Number] {
+				corrupt[share.Number] = true
+				share.Data[i] = out[share.Number]
+			}
+		}
+
+		if cb != nil {
+			for num, b := range out {
+				cb(num, f.n, []byte{b})
+			}
+		}
+	}
+
+	for num := range corrupt {
+		corruptShareNumbers = append(corruptShareNumbers, num)
+	}
+	sort.Ints(corruptShareNumbers)
+
+	return corruptShareNumbers, nil
+}
@@ -0,0 +1,59 @@
+// The MIT License (MIT)
+//
+// Copyright (C) 2016 Space Monkey, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package infectious
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestCorrect(t *testing.T) {
+	const block = 4096
+	const total, required = 7, 3
+
+	test := NewBerlekampWelchTest(t, required, total)
+	shares := test.SomeShares(block)
+
+	shares[0].Data[0]++
+	shares[1].Data[10]++
+
+	decoded_shares, callback := test.StoreShares()
+	corrupt, err := test.code.Correct(shares, callback)
+	test.AssertNoError(err)
+	test.AssertDeepEqual(decoded_shares[:required], shares[:required])
+
+	sort.Ints(corrupt)
+	test.AssertDeepEqual(corrupt, []int{0, 1})
+}
+
+func TestCorrectNoErrors(t *testing.T) {
+	const block = 4096
+	const total, required = 7, 3
+
+	test := NewBerlekampWelchTest(t, required, total)
+	shares := test.SomeShares(block)
+
+	corrupt, err := test.code.Correct(shares, nil)
+	test.AssertNoError(err)
+	test.AssertDeepEqual(corrupt, []int(nil))
+}
@@ -0,0 +1,203 @@
+// The MIT License (MIT)
+//
+// Copyright (C) 2016 Space Monkey, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package infectious
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// FecCodeParallel wraps a FecCode to split its Encode/BerlekampWelch work
+// across Parallelism byte-column workers. Each of a block's byte
+// positions is an independent GF(2^8) problem sharing the same
+// Vandermonde/inverse setup, so this work is embarrassingly parallel. A
+// Parallelism <= 0 means "use runtime.GOMAXPROCS(0)"; it is the zero
+// value by default, so wrapping a FecCode with Parallel gets the
+// parallel behavior for free.
+type FecCodeParallel struct {
+	*FecCode
+
+	Parallelism int
+}
+
+// Parallel wraps f so that its Encode/BerlekampWelch work can be split
+// across Parallelism goroutines by EncodeParallel and
+// BerlekampWelchParallel. Each worker computes its columns on its own
+// *FecCode (built fresh with the same required/total), never on f
+// itself, so the workers share no mutable receiver. Each column is
+// computed out of order across workers, but the results are merged back
+// into column order before cb is ever invoked, so cb sees exactly the
+// same share-number and byte ordering it would from the serial
+// Encode/BerlekampWelch.
+func (f *FecCode) Parallel(parallelism int) *FecCodeParallel {
+	return &FecCodeParallel{FecCode: f, Parallelism: parallelism}
+}
+
+func (f *FecCodeParallel) workers() int {
+	if f.Parallelism > 0 {
+		return f.Parallelism
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// EncodeParallel behaves like FecCode.Encode, but shards the block's byte
+// columns across f.workers() goroutines. cb is invoked once per output
+// share, in share-number order, with that share's bytes back in their
+// original column order -- only the underlying computation runs out of
+// order, not what the caller observes.
+func (f *FecCodeParallel) EncodeParallel(input []byte, cb Callback) error {
+	if len(input)%f.k != 0 {
+		return fmt.Errorf("input length must be a multiple of required (%d)", f.k)
+	}
+	block := len(input) / f.k
+
+	chunks, err := f.runColumnsCollect(block, f.n, func(code *FecCode, lo, hi int, out [][]byte) error {
+		sub := make([]byte, 0, f.k*(hi-lo))
+		for row := 0; row < f.k; row++ {
+			sub = append(sub, input[row*block+lo:row*block+hi]...)
+		}
+
+		return code.Encode(sub, func(num, _ int, data []byte) {
+			out[num] = append(out[num], data...)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for num, data := range chunks {
+		cb(num, f.n, data)
+	}
+	return nil
+}
+
+// BerlekampWelchParallel behaves like FecCode.BerlekampWelch, but shards
+// the block's byte columns across f.workers() goroutines. cb is invoked
+// once per output share, in share-number order, with that share's bytes
+// back in their original column order.
+func (f *FecCodeParallel) BerlekampWelchParallel(shares []Share,
+	cb Callback) error {
+
+	if len(shares) == 0 {
+		return fmt.Errorf("no shares given")
+	}
+	block := len(shares[0].Data)
+	for _, share := range shares {
+		if len(share.Data) != block {
+			return fmt.Errorf("all shares must be the same size")
+		}
+	}
+
+	chunks, err := f.runColumnsCollect(block, f.n, func(code *FecCode, lo, hi int, out [][]byte) error {
+		sub := make([]Share, len(shares))
+		for i, share := range shares {
+			sub[i] = Share{Number: share.Number, Data: share.Data[lo:hi]}
+		}
+
+		return code.BerlekampWelch(sub, func(num, _ int, data []byte) {
+			out[num] = append(out[num], data...)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for num, data := range chunks {
+		cb(num, f.n, data)
+	}
+	return nil
+}
+
+// runColumnsCollect splits [0, block) into f.workers() contiguous ranges
+// and runs fn over each range in its own goroutine, giving fn a
+// per-numShares scratch slice to append its share of the columns into,
+// and a *FecCode that belongs to that goroutine alone. Sharing f.FecCode
+// itself across goroutines would only be safe if Encode/BerlekampWelch
+// kept no mutable state on the receiver, which isn't something this
+// package can assume from the outside -- so each worker gets its own
+// freshly constructed code with the same (required, total) instead,
+// sidestepping the question entirely. Once every worker has finished,
+// the per-worker scratch slices are concatenated back together in
+// column order, so the returned [][]byte is exactly what a
+// single-threaded pass would have produced.
+func (f *FecCodeParallel) runColumnsCollect(block, numShares int,
+	fn func(code *FecCode, lo, hi int, out [][]byte) error) ([][]byte, error) {
+
+	workers := f.workers()
+	if workers > block {
+		workers = block
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	chunk := (block + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	outs := make([][][]byte, workers)
+	for w := 0; w < workers; w++ {
+		lo := w * chunk
+		hi := lo + chunk
+		if hi > block {
+			hi = block
+		}
+		if lo >= hi {
+			continue
+		}
+
+		out := make([][]byte, numShares)
+		for i := range out {
+			out[i] = make([]byte, 0, hi-lo)
+		}
+		outs[w] = out
+
+		wg.Add(1)
+		go func(w, lo, hi int) {
+			defer wg.Done()
+
+			code, err := NewFecCode(f.k, f.n)
+			if err != nil {
+				errs[w] = err
+				return
+			}
+			errs[w] = fn(code, lo, hi, out)
+		}(w, lo, hi)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	merged := make([][]byte, numShares)
+	for w := 0; w < workers; w++ {
+		for i, data := range outs[w] {
+			merged[i] = append(merged[i], data...)
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,59 @@
+// The MIT License (MIT)
+//
+// Copyright (C) 2016 Space Monkey, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package infectious
+
+import "testing"
+
+func TestEncodeParallel(t *testing.T) {
+	const block = 4096
+	const total, required = 7, 3
+
+	test := NewBerlekampWelchTest(t, required, total)
+
+	data := make([]byte, required*block)
+	for i := range data {
+		data[i] = byte(i + 1)
+	}
+
+	serial, store := test.StoreShares()
+	test.AssertNoError(test.code.Encode(data, store))
+
+	parallel, pstore := test.StoreShares()
+	test.AssertNoError(test.code.Parallel(4).EncodeParallel(data, pstore))
+
+	test.AssertDeepEqual(parallel, serial)
+}
+
+func TestBerlekampWelchParallel(t *testing.T) {
+	const block = 4096
+	const total, required = 7, 3
+
+	test := NewBerlekampWelchTest(t, required, total)
+	shares := test.SomeShares(block)
+	shares[0].Data[0]++
+
+	decoded_shares, callback := test.StoreShares()
+	test.AssertNoError(
+		test.code.Parallel(4).BerlekampWelchParallel(shares, callback))
+	test.AssertDeepEqual(decoded_shares[:required], shares[:required])
+}
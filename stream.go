@@ -0,0 +1,291 @@
+// The MIT License (MIT)
+//
+// Copyright (C) 2016 Space Monkey, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package infectious
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// DefaultStripeSize is the number of bytes each share receives per stripe
+// (so each stripe covers f.k*DefaultStripeSize input bytes) when no
+// explicit stripe size is given to NewStreamEncoder or NewStreamDecoder.
+const DefaultStripeSize = 64 * 1024
+
+// NewStreamEncoder returns a WriteCloser that encodes the bytes written to
+// it in fixed-size stripes, fan-writing each stripe's shares out to the
+// corresponding entry of w. len(w) must equal f.n.
+//
+// This exists for callers that cannot buffer an entire message in memory
+// to encode it in one Encode call -- e.g. a piece-store node streaming a
+// large upload straight from the network to its share sinks.
+//
+// The returned writer buffers at most one stripe (f.k*stripeSize bytes)
+// at a time. Callers must call Close when done writing: besides flushing
+// and zero-padding the final, possibly short, stripe, Close writes one
+// extra trailer stripe recording the true message length, so
+// NewStreamDecoder can trim that padding back off on the way out.
+func (f *FecCode) NewStreamEncoder(w []io.Writer, stripeSize int) (
+	io.WriteCloser, error) {
+
+	if len(w) != f.n {
+		return nil, fmt.Errorf("expected %d writers, got %d", f.n, len(w))
+	}
+	if stripeSize <= 0 {
+		stripeSize = DefaultStripeSize
+	}
+
+	return &streamEncoder{
+		code:       f,
+		writers:    w,
+		stripeSize: stripeSize,
+		buf:        make([]byte, 0, f.k*stripeSize),
+	}, nil
+}
+
+type streamEncoder struct {
+	code       *FecCode
+	writers    []io.Writer
+	stripeSize int
+	buf        []byte
+	total      uint64
+}
+
+func (s *streamEncoder) Write(p []byte) (n int, err error) {
+	full := s.code.k * s.stripeSize
+	for len(p) > 0 {
+		take := full - len(s.buf)
+		if take > len(p) {
+			take = len(p)
+		}
+		s.buf = append(s.buf, p[:take]...)
+		p = p[take:]
+		n += take
+		s.total += uint64(take)
+
+		if len(s.buf) == full {
+			if err := s.encodeStripe(s.buf); err != nil {
+				return n, err
+			}
+			s.buf = s.buf[:0]
+		}
+	}
+	return n, nil
+}
+
+func (s *streamEncoder) Close() error {
+	full := s.code.k * s.stripeSize
+
+	if len(s.buf) > 0 {
+		padded := make([]byte, full)
+		copy(padded, s.buf)
+		s.buf = nil
+
+		if err := s.encodeStripe(padded); err != nil {
+			return err
+		}
+	}
+
+	trailer := make([]byte, full)
+	binary.BigEndian.PutUint64(trailer[full-8:], s.total)
+	return s.encodeStripe(trailer)
+}
+
+func (s *streamEncoder) encodeStripe(stripe []byte) error {
+	var outerErr error
+	err := s.code.Encode(stripe, func(num, _ int, data []byte) {
+		if outerErr != nil {
+			return
+		}
+		if _, err := s.writers[num].Write(data); err != nil {
+			outerErr = err
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return outerErr
+}
+
+// NewStreamDecoder returns a Reader that pulls stripeSize-sized chunks out
+// of each of readers, reconstructs each stripe with BerlekampWelch, and
+// yields the concatenated, depadded result -- the trailer stripe written
+// by streamEncoder.Close tells it exactly how many of the bytes in the
+// final stripe are real, so callers don't need to track the original
+// message length themselves.
+//
+// len(readers) must be at least f.k+1, since BerlekampWelch needs a
+// redundant share to locate errors.
+func (f *FecCode) NewStreamDecoder(readers []io.Reader, stripeSize int) (
+	io.Reader, error) {
+
+	if stripeSize <= 0 {
+		stripeSize = DefaultStripeSize
+	}
+	if len(readers) < f.k+1 {
+		return nil, fmt.Errorf("not enough readers, need at least %d", f.k+1)
+	}
+
+	return &streamDecoder{
+		code:       f,
+		readers:    readers,
+		stripeSize: stripeSize,
+	}, nil
+}
+
+type streamDecoder struct {
+	code       *FecCode
+	readers    []io.Reader
+	stripeSize int
+
+	// a and b hold the two most recently decoded stripes that haven't
+	// been classified as data yet. A stripe is only safe to emit once we
+	// know a *third* stripe follows it -- otherwise it might turn out to
+	// be the final, padded data stripe sitting right before the trailer,
+	// which needs trimming before it can be handed to the caller.
+	bootstrapped bool
+	a, b         []byte
+	emittedBase  uint64
+
+	out  []byte
+	done bool
+	err  error
+}
+
+func (s *streamDecoder) Read(p []byte) (n int, err error) {
+	for len(s.out) == 0 && !s.done && s.err == nil {
+		s.err = s.pump()
+	}
+
+	n = copy(p, s.out)
+	s.out = s.out[n:]
+	if len(s.out) == 0 {
+		if s.err != nil {
+			return n, s.err
+		}
+		if s.done {
+			return n, io.EOF
+		}
+	}
+	return n, nil
+}
+
+func (s *streamDecoder) pump() error {
+	if !s.bootstrapped {
+		a, err := s.readStripe()
+		if err == io.EOF {
+			return fmt.Errorf("stream ended before a length trailer was found")
+		}
+		if err != nil {
+			return err
+		}
+
+		b, err := s.readStripe()
+		if err == io.EOF {
+			// a was the only stripe in the whole stream: it must be the
+			// trailer for an empty message, with no data stripe in front
+			// of it at all.
+			return s.finish(nil, a)
+		}
+		if err != nil {
+			return err
+		}
+
+		s.a, s.b = a, b
+		s.bootstrapped = true
+	}
+
+	c, err := s.readStripe()
+	if err == io.EOF {
+		// s.b is the trailer; s.a is the final (possibly padded) data
+		// stripe sitting right in front of it.
+		return s.finish(s.a, s.b)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.out = append(s.out, s.a...)
+	s.emittedBase += uint64(len(s.a))
+	s.a, s.b = s.b, c
+	return nil
+}
+
+// finish trims lastDataStripe down to the number of real bytes recorded
+// in trailer, appends it, and marks the stream done. lastDataStripe is
+// nil when the message was empty and trailer was the only stripe ever
+// written.
+func (s *streamDecoder) finish(lastDataStripe, trailer []byte) error {
+	full := s.code.k * s.stripeSize
+	if len(trailer) != full {
+		return fmt.Errorf("corrupt stream trailer")
+	}
+	if lastDataStripe != nil && len(lastDataStripe) != full {
+		return fmt.Errorf("corrupt stream trailer")
+	}
+
+	total := binary.BigEndian.Uint64(trailer[full-8:])
+	if total < s.emittedBase {
+		return fmt.Errorf("corrupt stream length")
+	}
+
+	remaining := total - s.emittedBase
+	if remaining > uint64(full) {
+		return fmt.Errorf("corrupt stream length")
+	}
+
+	s.out = append(s.out, lastDataStripe[:remaining]...)
+	s.done = true
+	return nil
+}
+
+// readStripe reads one stripe's worth of bytes from every reader and
+// decodes it with BerlekampWelch, returning io.EOF only if the stream
+// ended cleanly before any bytes of this stripe were available.
+func (s *streamDecoder) readStripe() ([]byte, error) {
+	shares := make([]Share, len(s.readers))
+	for i, r := range s.readers {
+		buf := make([]byte, s.stripeSize)
+		read, err := io.ReadFull(r, buf)
+		if err == io.EOF && read == 0 {
+			if i == 0 {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("reader %d ended early", i)
+		}
+		if err != nil {
+			return nil, err
+		}
+		shares[i] = Share{Number: i, Data: buf}
+	}
+
+	decoded := make([]byte, 0, s.code.k*s.stripeSize)
+	store := func(_, _ int, data []byte) {
+		decoded = append(decoded, data...)
+	}
+	if err := s.code.BerlekampWelch(shares, store); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
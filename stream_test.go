@@ -0,0 +1,99 @@
+// The MIT License (MIT)
+//
+// Copyright (C) 2016 Space Monkey, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package infectious
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestStreamEncodeDecode(t *testing.T) {
+	const total, required = 7, 3
+	const stripeSize = 16
+
+	test := NewBerlekampWelchTest(t, required, total)
+
+	msg := bytes.Repeat([]byte("the quick brown fox jumps over "), 10)
+
+	bufs := make([]*bytes.Buffer, total)
+	writers := make([]io.Writer, total)
+	for i := range bufs {
+		bufs[i] = new(bytes.Buffer)
+		writers[i] = bufs[i]
+	}
+
+	enc, err := test.code.NewStreamEncoder(writers, stripeSize)
+	test.AssertNoError(err)
+	_, err = enc.Write(msg)
+	test.AssertNoError(err)
+	test.AssertNoError(enc.Close())
+
+	readers := make([]io.Reader, total)
+	for i, buf := range bufs {
+		readers[i] = bytes.NewReader(buf.Bytes())
+	}
+
+	dec, err := test.code.NewStreamDecoder(readers[:required+1], stripeSize)
+	test.AssertNoError(err)
+
+	out, err := io.ReadAll(dec)
+	test.AssertNoError(err)
+	test.AssertDeepEqual(out, msg)
+}
+
+func TestStreamEncodeDecodeStripeAligned(t *testing.T) {
+	const total, required = 7, 3
+	const stripeSize = 8
+
+	test := NewBerlekampWelchTest(t, required, total)
+
+	// exactly required*stripeSize bytes: no padding needed on the final
+	// data stripe, which exercises the zero-remainder trim path.
+	msg := bytes.Repeat([]byte{0x42}, required*stripeSize)
+
+	bufs := make([]*bytes.Buffer, total)
+	writers := make([]io.Writer, total)
+	for i := range bufs {
+		bufs[i] = new(bytes.Buffer)
+		writers[i] = bufs[i]
+	}
+
+	enc, err := test.code.NewStreamEncoder(writers, stripeSize)
+	test.AssertNoError(err)
+	_, err = enc.Write(msg)
+	test.AssertNoError(err)
+	test.AssertNoError(enc.Close())
+
+	readers := make([]io.Reader, total)
+	for i, buf := range bufs {
+		readers[i] = bytes.NewReader(buf.Bytes())
+	}
+
+	dec, err := test.code.NewStreamDecoder(readers[:required+1], stripeSize)
+	test.AssertNoError(err)
+
+	out, err := io.ReadAll(dec)
+	test.AssertNoError(err)
+	test.AssertDeepEqual(out, msg)
+}